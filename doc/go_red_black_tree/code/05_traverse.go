@@ -38,6 +38,38 @@ type Node struct {
 
 	// Right is a right child Node.
 	Right *Node
+
+	// Parent points back to the Node's parent, or nil at the Root.
+	// It is maintained by insert, RotateToLeft, RotateToRight, and
+	// delete so that Successor and Predecessor can walk the Tree
+	// without recursion or a stack.
+	Parent *Node
+
+	// Size is the number of Nodes in the sub-Tree rooted at this
+	// Node, itself included. It is kept up to date by insert,
+	// RotateToLeft, and RotateToRight so Rank and Select run in
+	// O(log n) instead of walking the whole sub-Tree.
+	Size int
+
+	// Frozen marks a Node as belonging to a PersistentTree snapshot.
+	// insert, RotateToLeft, RotateToRight, and FlipColor clone a
+	// frozen Node before mutating it instead of writing through it.
+	Frozen bool
+
+	// Height is the height of the sub-Tree rooted at this Node when
+	// it belongs to an AVLTree (1 for a leaf, 0 for nil). It is
+	// unused by the LLRB Tree above; see 07_avl.go.
+	Height int
+}
+
+// clone returns a shallow, unfrozen copy of nd so it is safe to
+// mutate. Its children are left as they are (still shared, and still
+// Frozen if nd was), and get cloned themselves only if a deeper call
+// needs to mutate them too.
+func clone(nd *Node) *Node {
+	cp := *nd
+	cp.Frozen = false
+	return &cp
 }
 
 // NewNode returns a new Node.
@@ -45,9 +77,18 @@ func NewNode(key Interface) *Node {
 	nd := &Node{}
 	nd.Key = key
 	nd.Black = false
+	nd.Size = 1
 	return nd
 }
 
+// size returns the subtree count rooted at nd, or 0 for a nil Node.
+func size(nd *Node) int {
+	if nd == nil {
+		return 0
+	}
+	return nd.Size
+}
+
 func (tr *Tree) String() string {
 	return tr.Root.String()
 }
@@ -79,15 +120,21 @@ func (nd1 *Node) insert(nd2 *Node) *Node {
 	if nd1 == nil {
 		return nd2
 	}
+	if nd1.Frozen {
+		nd1 = clone(nd1)
+	}
 	if nd1.Key.Less(nd2.Key) {
 		// nd1 is smaller than nd2
 		// nd1 < nd2
 		nd1.Right = nd1.Right.insert(nd2)
+		nd1.Right.Parent = nd1
 	} else {
 		// nd1 is greater than nd2
 		// nd1 >= nd2
 		nd1.Left = nd1.Left.insert(nd2)
+		nd1.Left.Parent = nd1
 	}
+	nd1.Size = 1 + size(nd1.Left) + size(nd1.Right)
 	// Balance from nd1
 	return Balance(nd1)
 }
@@ -110,6 +157,7 @@ func (tr *Tree) Insert(nd *Node) {
 
 	// Root node must be always black.
 	tr.Root.Black = true
+	tr.Root.Parent = nil
 }
 
 // RotateToLeft runs when there is a right-leaning link.
@@ -120,15 +168,31 @@ func RotateToLeft(nd *Node) *Node {
 		panic("Can't rotate a black link")
 	}
 
+	if nd.Frozen {
+		nd = clone(nd)
+	}
+
 	// exchange x and nd
 	// nd is parent node, x is Right child
 	x := nd.Right
+	if x.Frozen {
+		x = clone(x)
+	}
 	nd.Right = x.Left
+	if nd.Right != nil && !nd.Right.Frozen {
+		nd.Right.Parent = nd
+	}
 	x.Left = nd
 
+	x.Parent = nd.Parent
+	nd.Parent = x
+
 	x.Black = nd.Black
 	nd.Black = false
 
+	nd.Size = 1 + size(nd.Left) + size(nd.Right)
+	x.Size = 1 + size(x.Left) + size(x.Right)
+
 	return x
 }
 
@@ -140,36 +204,66 @@ func RotateToRight(nd *Node) *Node {
 		panic("Can't rotate a black link")
 	}
 
+	if nd.Frozen {
+		nd = clone(nd)
+	}
+
 	// exchange x and nd
 	// nd is parent node, x is Left child
 	x := nd.Left
+	if x.Frozen {
+		x = clone(x)
+	}
 	nd.Left = x.Right
+	if nd.Left != nil && !nd.Left.Frozen {
+		nd.Left.Parent = nd
+	}
 	x.Right = nd
 
+	x.Parent = nd.Parent
+	nd.Parent = x
+
 	x.Black = nd.Black
 	nd.Black = false
 
+	nd.Size = 1 + size(nd.Left) + size(nd.Right)
+	x.Size = 1 + size(x.Left) + size(x.Right)
+
 	return x
 }
 
-// FlipColor flips the color.
-// Left and Right children must be present
-func FlipColor(nd *Node) {
-	// nd is parent node
+// FlipColor flips the color of nd and both its children.
+// Left and Right children must be present.
+//
+// If nd or either child belongs to a frozen PersistentTree snapshot,
+// FlipColor clones it first, so it never mutates a Node a reader
+// might still be walking; it returns the (possibly new) nd, which
+// callers must use in place of their original reference.
+func FlipColor(nd *Node) *Node {
+	if nd.Frozen {
+		nd = clone(nd)
+	}
+	if nd.Left.Frozen {
+		nd.Left = clone(nd.Left)
+	}
+	if nd.Right.Frozen {
+		nd.Right = clone(nd.Right)
+	}
 	nd.Black = !nd.Black
 	nd.Left.Black = !nd.Left.Black
 	nd.Right.Black = !nd.Right.Black
+	return nd
 }
 
 // MoveRedFromRightToLeft moves Red Node
 // from Right sub-Tree to Left sub-Tree.
 // Left and Right children must be present
 func MoveRedFromRightToLeft(nd *Node) *Node {
-	FlipColor(nd)
+	nd = FlipColor(nd)
 	if isRed(nd.Right.Left) {
 		nd.Right = RotateToRight(nd.Right)
 		nd = RotateToLeft(nd)
-		FlipColor(nd)
+		nd = FlipColor(nd)
 	}
 	return nd
 }
@@ -178,10 +272,10 @@ func MoveRedFromRightToLeft(nd *Node) *Node {
 // from Left sub-Tree to Right sub-Tree.
 // Left and Right children must be present
 func MoveRedFromLeftToRight(nd *Node) *Node {
-	FlipColor(nd)
+	nd = FlipColor(nd)
 	if isRed(nd.Left.Left) {
 		nd = RotateToRight(nd)
-		FlipColor(nd)
+		nd = FlipColor(nd)
 	}
 	return nd
 }
@@ -196,7 +290,7 @@ func Balance(nd *Node) *Node {
 		nd = RotateToRight(nd)
 	}
 	if isRed(nd.Left) && isRed(nd.Right) {
-		FlipColor(nd)
+		nd = FlipColor(nd)
 	}
 	return nd
 }
@@ -210,11 +304,333 @@ func FixUp(nd *Node) *Node {
 		nd = RotateToRight(nd)
 	}
 	if isRed(nd.Left) && isRed(nd.Right) {
-		FlipColor(nd)
+		nd = FlipColor(nd)
 	}
 	return nd
 }
 
+// deleteMin removes the smallest Node in the sub-Tree rooted at nd,
+// moving red links down the left spine with MoveRedFromRightToLeft
+// so that the removed leaf is never black, then fixes the Tree back
+// up with FixUp.
+func deleteMin(nd *Node) *Node {
+	if nd.Left == nil {
+		return nil
+	}
+	if nd.Frozen {
+		nd = clone(nd)
+	}
+	if !isRed(nd.Left) && !isRed(nd.Left.Left) {
+		nd = MoveRedFromRightToLeft(nd)
+	}
+	nd.Left = deleteMin(nd.Left)
+	if nd.Left != nil {
+		nd.Left.Parent = nd
+	}
+	nd.Size = 1 + size(nd.Left) + size(nd.Right)
+	return FixUp(nd)
+}
+
+// DeleteMin removes the Node with the smallest Key from the Tree
+// and returns a detached copy of it, or nil if the Tree is empty.
+func (tr *Tree) DeleteMin() *Node {
+	if tr.Root == nil {
+		return nil
+	}
+	min := tr.Min()
+	removed := &Node{Key: min.Key, Black: min.Black}
+
+	if !isRed(tr.Root.Left) && !isRed(tr.Root.Right) {
+		if tr.Root.Frozen {
+			tr.Root = clone(tr.Root)
+		}
+		tr.Root.Black = false
+	}
+	tr.Root = deleteMin(tr.Root)
+	if tr.Root != nil {
+		tr.Root.Black = true
+		tr.Root.Parent = nil
+	}
+	return removed
+}
+
+// deleteMax removes the largest Node in the sub-Tree rooted at nd,
+// moving red links down the right spine with MoveRedFromLeftToRight.
+func deleteMax(nd *Node) *Node {
+	if nd.Frozen {
+		nd = clone(nd)
+	}
+	if isRed(nd.Left) {
+		nd = RotateToRight(nd)
+	}
+	if nd.Right == nil {
+		return nil
+	}
+	if !isRed(nd.Right) && !isRed(nd.Right.Left) {
+		nd = MoveRedFromLeftToRight(nd)
+	}
+	nd.Right = deleteMax(nd.Right)
+	if nd.Right != nil {
+		nd.Right.Parent = nd
+	}
+	nd.Size = 1 + size(nd.Left) + size(nd.Right)
+	return FixUp(nd)
+}
+
+// DeleteMax removes the Node with the largest Key from the Tree
+// and returns a detached copy of it, or nil if the Tree is empty.
+func (tr *Tree) DeleteMax() *Node {
+	if tr.Root == nil {
+		return nil
+	}
+	max := tr.Max()
+	removed := &Node{Key: max.Key, Black: max.Black}
+
+	if !isRed(tr.Root.Left) && !isRed(tr.Root.Right) {
+		if tr.Root.Frozen {
+			tr.Root = clone(tr.Root)
+		}
+		tr.Root.Black = false
+	}
+	tr.Root = deleteMax(tr.Root)
+	if tr.Root != nil {
+		tr.Root.Black = true
+		tr.Root.Parent = nil
+	}
+	return removed
+}
+
+// deleteKey removes key from the sub-Tree rooted at nd, following the
+// standard LLRB delete: walk down moving red links onto the search
+// path (MoveRedFromRightToLeft/MoveRedFromLeftToRight) so the Node
+// being removed is never black, then swap in the in-order successor
+// when deleting from an internal Node, and FixUp on the way back.
+func deleteKey(nd *Node, key Interface) *Node {
+	if nd.Frozen {
+		nd = clone(nd)
+	}
+	if key.Less(nd.Key) {
+		if !isRed(nd.Left) && !isRed(nd.Left.Left) {
+			nd = MoveRedFromRightToLeft(nd)
+		}
+		nd.Left = deleteKey(nd.Left, key)
+		if nd.Left != nil {
+			nd.Left.Parent = nd
+		}
+	} else {
+		if isRed(nd.Left) {
+			nd = RotateToRight(nd)
+		}
+		if !key.Less(nd.Key) && !nd.Key.Less(key) && nd.Right == nil {
+			return nil
+		}
+		if !isRed(nd.Right) && !isRed(nd.Right.Left) {
+			nd = MoveRedFromLeftToRight(nd)
+		}
+		if !key.Less(nd.Key) && !nd.Key.Less(key) {
+			m := nd.Right
+			for m.Left != nil {
+				m = m.Left
+			}
+			nd.Key = m.Key
+			nd.Right = deleteMin(nd.Right)
+		} else {
+			nd.Right = deleteKey(nd.Right, key)
+		}
+		if nd.Right != nil {
+			nd.Right.Parent = nd
+		}
+	}
+	nd.Size = 1 + size(nd.Left) + size(nd.Right)
+	return FixUp(nd)
+}
+
+// Delete removes the Node with the given key from the Tree and
+// returns a detached copy of what was removed, or nil if key is not
+// found. The returned Node is a snapshot: the LLRB delete algorithm
+// swaps keys with the in-order successor internally, so the live
+// tree Node is not safe to hand back to the caller.
+func (tr *Tree) Delete(key Interface) *Node {
+	if tr.Root == nil || tr.Search(key) == nil {
+		return nil
+	}
+	found := tr.Search(key)
+	removed := &Node{Key: found.Key, Black: found.Black}
+
+	if !isRed(tr.Root.Left) && !isRed(tr.Root.Right) {
+		if tr.Root.Frozen {
+			tr.Root = clone(tr.Root)
+		}
+		tr.Root.Black = false
+	}
+	tr.Root = deleteKey(tr.Root, key)
+	if tr.Root != nil {
+		tr.Root.Black = true
+		tr.Root.Parent = nil
+	}
+	return removed
+}
+
+// Successor returns the in-order successor of nd: the Node that
+// follows nd in ascending Key order (it holds a Key equal to nd.Key
+// when the Tree has duplicates), or nil if nd is the last Node.
+// It walks Parent pointers instead of re-searching from the Root.
+func (nd *Node) Successor() *Node {
+	if nd == nil {
+		return nil
+	}
+	if nd.Right != nil {
+		m := nd.Right
+		for m.Left != nil {
+			m = m.Left
+		}
+		return m
+	}
+	cur, p := nd, nd.Parent
+	for p != nil && cur == p.Right {
+		cur, p = p, p.Parent
+	}
+	return p
+}
+
+// Predecessor returns the in-order predecessor of nd: the Node that
+// precedes nd in ascending Key order (it holds a Key equal to nd.Key
+// when the Tree has duplicates), or nil if nd is the first Node. It
+// walks Parent pointers instead of re-searching from
+// the Root.
+func (nd *Node) Predecessor() *Node {
+	if nd == nil {
+		return nil
+	}
+	if nd.Left != nil {
+		m := nd.Left
+		for m.Right != nil {
+			m = m.Right
+		}
+		return m
+	}
+	cur, p := nd, nd.Parent
+	for p != nil && cur == p.Left {
+		cur, p = p, p.Parent
+	}
+	return p
+}
+
+// Floor returns the Node with the largest Key less than or equal to
+// key, or nil if every Key in the Tree is greater than key.
+func (tr *Tree) Floor(key Interface) *Node {
+	return floor(tr.Root, key)
+}
+
+func floor(nd *Node, key Interface) *Node {
+	if nd == nil {
+		return nil
+	}
+	switch {
+	case !nd.Key.Less(key) && !key.Less(nd.Key):
+		return nd
+	case key.Less(nd.Key):
+		return floor(nd.Left, key)
+	default:
+		if f := floor(nd.Right, key); f != nil {
+			return f
+		}
+		return nd
+	}
+}
+
+// Ceiling returns the Node with the smallest Key greater than or
+// equal to key, or nil if every Key in the Tree is less than key.
+func (tr *Tree) Ceiling(key Interface) *Node {
+	return ceiling(tr.Root, key)
+}
+
+func ceiling(nd *Node, key Interface) *Node {
+	if nd == nil {
+		return nil
+	}
+	switch {
+	case !nd.Key.Less(key) && !key.Less(nd.Key):
+		return nd
+	case nd.Key.Less(key):
+		return ceiling(nd.Right, key)
+	default:
+		if c := ceiling(nd.Left, key); c != nil {
+			return c
+		}
+		return nd
+	}
+}
+
+// Rank returns the number of Keys in the Tree strictly less than
+// key.
+func (tr *Tree) Rank(key Interface) int {
+	return rank(tr.Root, key)
+}
+
+func rank(nd *Node, key Interface) int {
+	if nd == nil {
+		return 0
+	}
+	switch {
+	case key.Less(nd.Key):
+		return rank(nd.Left, key)
+	case nd.Key.Less(key):
+		return 1 + size(nd.Left) + rank(nd.Right, key)
+	default:
+		// Equal Keys can also sit in the left sub-Tree, so keep
+		// counting there instead of taking its whole size.
+		return rank(nd.Left, key)
+	}
+}
+
+// Select returns the Node holding the k-th smallest Key (0-indexed),
+// or nil if k is out of range.
+func (tr *Tree) Select(k int) *Node {
+	if k < 0 || k >= size(tr.Root) {
+		return nil
+	}
+	return selectNode(tr.Root, k)
+}
+
+func selectNode(nd *Node, k int) *Node {
+	t := size(nd.Left)
+	switch {
+	case k < t:
+		return selectNode(nd.Left, k)
+	case k > t:
+		return selectNode(nd.Right, k-t-1)
+	default:
+		return nd
+	}
+}
+
+// RangeInOrder visits every Node with a Key in [lo, hi], in ascending
+// Key order, stopping early if visit returns false.
+func (tr *Tree) RangeInOrder(lo, hi Interface, visit func(*Node) bool) {
+	rangeInOrder(tr.Root, lo, hi, visit)
+}
+
+func rangeInOrder(nd *Node, lo, hi Interface, visit func(*Node) bool) bool {
+	if nd == nil {
+		return true
+	}
+	if !nd.Key.Less(lo) {
+		if !rangeInOrder(nd.Left, lo, hi, visit) {
+			return false
+		}
+	}
+	if !nd.Key.Less(lo) && !hi.Less(nd.Key) {
+		if !visit(nd) {
+			return false
+		}
+	}
+	if !hi.Less(nd.Key) {
+		return rangeInOrder(nd.Right, lo, hi, visit)
+	}
+	return true
+}
+
 type Float64 float64
 
 // Less returns true if float64(a) < float64(b).
@@ -366,6 +782,10 @@ func main() {
 }
 
 // PreOrder traverses from Root, Left-SubTree, and Right-SubTree. (DFS)
+//
+// Deprecated: use Tree.PreOrderIterator instead. This spawns a
+// goroutine per call that leaks if the consumer stops reading ch
+// before it is closed (see ComparePreOrder).
 func (tr *Tree) PreOrder(ch chan string) {
 	preOrder(tr.Root, ch)
 	close(ch)
@@ -400,6 +820,10 @@ func ComparePreOrder(t1, t2 *Tree) bool {
 }
 
 // InOrder traverses from Left-SubTree, Root, and Right-SubTree. (DFS)
+//
+// Deprecated: use Tree.InOrderIterator instead. This spawns a
+// goroutine per call that leaks if the consumer stops reading ch
+// before it is closed (see CompareInOrder).
 func (tr *Tree) InOrder(ch chan string) {
 	inOrder(tr.Root, ch)
 	close(ch)
@@ -434,6 +858,10 @@ func CompareInOrder(t1, t2 *Tree) bool {
 }
 
 // PostOrder traverses from Left-SubTree, Right-SubTree, and Root.
+//
+// Deprecated: use Tree.PostOrderIterator instead. This spawns a
+// goroutine per call that leaks if the consumer stops reading ch
+// before it is closed (see ComparePostOrder).
 func (tr *Tree) PostOrder(ch chan string) {
 	postOrder(tr.Root, ch)
 	close(ch)
@@ -497,3 +925,299 @@ func (tr *Tree) LevelOrder() []*Node {
 	}
 	return visited
 }
+
+// InOrderIterator walks a Tree in ascending Key order with an
+// explicit stack, so repeated traversals allocate once (at
+// Tree.InOrderIterator) instead of per call, and a caller that stops
+// early never leaks a goroutine the way InOrder does.
+type InOrderIterator struct {
+	root  *Node
+	stack []*Node
+	nd    *Node
+}
+
+// InOrderIterator returns an Iterator positioned before the smallest
+// Key in the Tree.
+func (tr *Tree) InOrderIterator() *InOrderIterator {
+	it := &InOrderIterator{root: tr.Root}
+	it.Reset()
+	return it
+}
+
+// Reset repositions the Iterator back to the smallest Key so it can
+// be walked again without allocating a new one.
+func (it *InOrderIterator) Reset() {
+	it.stack = it.stack[:0]
+	it.nd = it.root
+}
+
+// Next returns the next Node in ascending Key order, or (nil, false)
+// once the traversal is exhausted.
+func (it *InOrderIterator) Next() (*Node, bool) {
+	for it.nd != nil || len(it.stack) > 0 {
+		for it.nd != nil {
+			it.stack = append(it.stack, it.nd)
+			it.nd = it.nd.Left
+		}
+		nd := it.stack[len(it.stack)-1]
+		it.stack = it.stack[:len(it.stack)-1]
+		it.nd = nd.Right
+		return nd, true
+	}
+	return nil, false
+}
+
+// PreOrderIterator walks a Tree Root, Left-SubTree, Right-SubTree
+// with an explicit stack; see InOrderIterator.
+type PreOrderIterator struct {
+	root  *Node
+	stack []*Node
+}
+
+// PreOrderIterator returns an Iterator positioned at the Root.
+func (tr *Tree) PreOrderIterator() *PreOrderIterator {
+	it := &PreOrderIterator{root: tr.Root}
+	it.Reset()
+	return it
+}
+
+// Reset repositions the Iterator back to the Root.
+func (it *PreOrderIterator) Reset() {
+	it.stack = it.stack[:0]
+	if it.root != nil {
+		it.stack = append(it.stack, it.root)
+	}
+}
+
+// Next returns the next Node in pre-order, or (nil, false) once the
+// traversal is exhausted.
+func (it *PreOrderIterator) Next() (*Node, bool) {
+	if len(it.stack) == 0 {
+		return nil, false
+	}
+	nd := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	if nd.Right != nil {
+		it.stack = append(it.stack, nd.Right)
+	}
+	if nd.Left != nil {
+		it.stack = append(it.stack, nd.Left)
+	}
+	return nd, true
+}
+
+// PostOrderIterator walks a Tree Left-SubTree, Right-SubTree, Root
+// with an explicit stack; see InOrderIterator.
+type PostOrderIterator struct {
+	root    *Node
+	stack   []*Node
+	visited *Node
+}
+
+// PostOrderIterator returns an Iterator positioned at the Root.
+func (tr *Tree) PostOrderIterator() *PostOrderIterator {
+	it := &PostOrderIterator{root: tr.Root}
+	it.Reset()
+	return it
+}
+
+// Reset repositions the Iterator back to the Root.
+func (it *PostOrderIterator) Reset() {
+	it.stack = it.stack[:0]
+	if it.root != nil {
+		it.stack = append(it.stack, it.root)
+	}
+	it.visited = nil
+}
+
+// Next returns the next Node in post-order, or (nil, false) once the
+// traversal is exhausted.
+func (it *PostOrderIterator) Next() (*Node, bool) {
+	for len(it.stack) > 0 {
+		nd := it.stack[len(it.stack)-1]
+		if (nd.Left == nil && nd.Right == nil) ||
+			(it.visited != nil && (it.visited == nd.Left || it.visited == nd.Right)) {
+			it.stack = it.stack[:len(it.stack)-1]
+			it.visited = nd
+			return nd, true
+		}
+		if nd.Right != nil {
+			it.stack = append(it.stack, nd.Right)
+		}
+		if nd.Left != nil {
+			it.stack = append(it.stack, nd.Left)
+		}
+	}
+	return nil, false
+}
+
+// LevelIterator walks a Tree breadth-first, yielding each Node with
+// its depth from the Root (0 at the Root), so callers can group
+// Nodes into levels (as in the LeetCode level-order problems)
+// without re-walking the Tree.
+type LevelIterator struct {
+	root  *Node
+	queue []levelEntry
+}
+
+type levelEntry struct {
+	nd    *Node
+	depth int
+}
+
+// LevelOrderIter returns a LevelIterator positioned at the Root.
+func (tr *Tree) LevelOrderIter() *LevelIterator {
+	it := &LevelIterator{root: tr.Root}
+	it.Reset()
+	return it
+}
+
+// Reset repositions the LevelIterator back to the Root.
+func (it *LevelIterator) Reset() {
+	it.queue = it.queue[:0]
+	if it.root != nil {
+		it.queue = append(it.queue, levelEntry{it.root, 0})
+	}
+}
+
+// Next returns the next Node in breadth-first order together with
+// its depth from the Root, or (nil, 0, false) once exhausted.
+func (it *LevelIterator) Next() (*Node, int, bool) {
+	if len(it.queue) == 0 {
+		return nil, 0, false
+	}
+	e := it.queue[0]
+	it.queue = it.queue[1:len(it.queue):len(it.queue)]
+	if e.nd.Left != nil {
+		it.queue = append(it.queue, levelEntry{e.nd.Left, e.depth + 1})
+	}
+	if e.nd.Right != nil {
+		it.queue = append(it.queue, levelEntry{e.nd.Right, e.depth + 1})
+	}
+	return e.nd, e.depth, true
+}
+
+// Levels groups the Tree's Nodes by depth from the Root, using
+// LevelOrderIter so the Tree is walked exactly once.
+func (tr *Tree) Levels() [][]*Node {
+	it := tr.LevelOrderIter()
+	var levels [][]*Node
+	for {
+		nd, depth, ok := it.Next()
+		if !ok {
+			break
+		}
+		if depth == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[depth] = append(levels[depth], nd)
+	}
+	return levels
+}
+
+// PersistentTree is an immutable snapshot of a Tree obtained from
+// Tree.Snapshot. Insert and Delete never mutate the snapshot in
+// place: they path-copy the O(log n) Nodes on the search path and
+// return a new *PersistentTree, sharing every other subtree with the
+// snapshot it was taken from. A reader holding an older
+// PersistentTree keeps seeing a stable ordering while a writer keeps
+// producing newer ones.
+//
+// PersistentTree does not maintain Parent pointers below the Nodes
+// it clones, so Node.Successor/Predecessor are not reliable on a
+// Node obtained from a PersistentTree; use Floor/Ceiling/Rank/Select
+// (or RangeInOrder/InOrder, both driven from the root) instead. The
+// same holds for the source Tree once Snapshot has been called: a
+// Node it clones shares its children, whose Parent still points at
+// the frozen original.
+type PersistentTree struct {
+	root *Node
+	size int
+}
+
+// Snapshot freezes every Node reachable from tr.Root and returns a
+// PersistentTree over them. tr itself remains a regular, mutable
+// Tree: the next Insert/Delete on tr clones whatever frozen Nodes it
+// needs to touch instead of mutating them, so the snapshot stays
+// valid.
+func (tr *Tree) Snapshot() *PersistentTree {
+	freeze(tr.Root)
+	return &PersistentTree{root: tr.Root, size: size(tr.Root)}
+}
+
+func freeze(nd *Node) {
+	if nd == nil || nd.Frozen {
+		return
+	}
+	nd.Frozen = true
+	freeze(nd.Left)
+	freeze(nd.Right)
+}
+
+// Len returns the number of Nodes in pt.
+func (pt *PersistentTree) Len() int { return pt.size }
+
+// Search does binary search for key without locks: pt.root and every
+// Node beneath it are frozen, so concurrent readers of pt never race
+// with each other or with a writer building the next snapshot.
+func (pt *PersistentTree) Search(key Interface) *Node {
+	nd := pt.root
+	for nd != nil {
+		switch {
+		case nd.Key.Less(key):
+			nd = nd.Right
+		case key.Less(nd.Key):
+			nd = nd.Left
+		default:
+			return nd
+		}
+	}
+	return nil
+}
+
+// InOrder returns every Node in pt in ascending Key order. Like
+// Search, it is safe to call with no locking while other goroutines
+// read pt or derive newer PersistentTrees from it.
+func (pt *PersistentTree) InOrder() []*Node {
+	it := &InOrderIterator{root: pt.root}
+	it.Reset()
+	var nodes []*Node
+	for {
+		nd, ok := it.Next()
+		if !ok {
+			return nodes
+		}
+		nodes = append(nodes, nd)
+	}
+}
+
+// Insert returns a new PersistentTree with nd added, sharing every
+// subtree the insert path didn't touch with pt.
+func (pt *PersistentTree) Insert(nd *Node) *PersistentTree {
+	root := pt.root.insert(nd)
+	root.Black = true
+	root.Parent = nil
+	freeze(root)
+	return &PersistentTree{root: root, size: pt.size + 1}
+}
+
+// Delete returns a new PersistentTree with key removed, sharing every
+// subtree the delete path didn't touch with pt. It returns pt itself
+// if key is not present.
+func (pt *PersistentTree) Delete(key Interface) *PersistentTree {
+	if pt.Search(key) == nil {
+		return pt
+	}
+	root := pt.root
+	if !isRed(root.Left) && !isRed(root.Right) {
+		root = clone(root)
+		root.Black = false
+	}
+	root = deleteKey(root, key)
+	if root != nil {
+		root.Black = true
+		root.Parent = nil
+	}
+	freeze(root)
+	return &PersistentTree{root: root, size: pt.size - 1}
+}
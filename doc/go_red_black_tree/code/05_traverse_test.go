@@ -0,0 +1,574 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// blackHeight walks every root-to-nil path under nd and returns the
+// common black-height, or -1 if any two paths disagree or a red Node
+// has a red child.
+func blackHeight(nd *Node) int {
+	if nd == nil {
+		return 0
+	}
+	if isRed(nd) && (isRed(nd.Left) || isRed(nd.Right)) {
+		return -1
+	}
+	left := blackHeight(nd.Left)
+	if left == -1 {
+		return -1
+	}
+	right := blackHeight(nd.Right)
+	if right == -1 {
+		return -1
+	}
+	if left != right {
+		return -1
+	}
+	if nd.Black {
+		return left + 1
+	}
+	return left
+}
+
+// checkInvariants asserts the LLRB invariants on tr: the Root is
+// black, no red Node has a red child, and every root-to-nil path has
+// the same black-height.
+func checkInvariants(t *testing.T, tr *Tree, step string) {
+	t.Helper()
+	if tr.Root == nil {
+		return
+	}
+	if !tr.Root.Black {
+		t.Fatalf("%s: Root is red", step)
+	}
+	if blackHeight(tr.Root) == -1 {
+		t.Fatalf("%s: red-black invariant violated:\n%s", step, tr.String())
+	}
+}
+
+// checkAgreement asserts that tr holds exactly the multiset of Keys
+// recorded in present (a count per Key, present's own oracle for what
+// is currently in the Tree), both in Search membership and in the
+// full in-order Key sequence. Comparing the whole sequence, not just
+// the Key an operation just touched, is what would have caught
+// deleteKey picking the wrong Node to remove (fixed in 5199a16): that
+// bug could leave the requested Key in the Tree while silently
+// dropping a different one.
+func checkAgreement(t *testing.T, tr *Tree, present map[Float64]int, step string) {
+	t.Helper()
+
+	var want []Float64
+	for k, count := range present {
+		for i := 0; i < count; i++ {
+			want = append(want, k)
+		}
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+
+	var got []Float64
+	it := tr.InOrderIterator()
+	for {
+		nd, ok := it.Next()
+		if !ok {
+			break
+		}
+		got = append(got, nd.Key.(Float64))
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: Tree Keys = %v, want %v", step, got, want)
+	}
+	for k := range present {
+		if tr.Search(k) == nil {
+			t.Fatalf("%s: Search(%v) = nil, want a Node", step, k)
+		}
+	}
+}
+
+// minKey and maxKey return the smallest/largest Key with a positive
+// count in present, and whether present is non-empty.
+func minKey(present map[Float64]int) (Float64, bool) {
+	var min Float64
+	found := false
+	for k := range present {
+		if !found || k < min {
+			min = k
+			found = true
+		}
+	}
+	return min, found
+}
+
+func maxKey(present map[Float64]int) (Float64, bool) {
+	var max Float64
+	found := false
+	for k := range present {
+		if !found || k > max {
+			max = k
+			found = true
+		}
+	}
+	return max, found
+}
+
+// FuzzInsertDelete inserts and deletes random Float64 keys and checks
+// the red-black invariants hold after every single Insert, Delete,
+// DeleteMin, and DeleteMax, and that the Tree's contents agree with a
+// map[Float64]int oracle mirroring the same operations.
+func FuzzInsertDelete(f *testing.F) {
+	f.Add(int64(1), uint8(64))
+	f.Add(int64(42), uint8(200))
+
+	f.Fuzz(func(t *testing.T, seed int64, n uint8) {
+		rnd := newRand(seed)
+
+		init := Float64(rnd())
+		tr := New(NewNode(init))
+		present := map[Float64]int{init: 1}
+		checkInvariants(t, tr, "init")
+		checkAgreement(t, tr, present, "init")
+
+		drop := func(key Float64) {
+			present[key]--
+			if present[key] == 0 {
+				delete(present, key)
+			}
+		}
+
+		for i := 0; i < int(n); i++ {
+			key := Float64(rnd())
+			switch int(key) % 4 {
+			case 0:
+				want, ok := minKey(present)
+				removed := tr.DeleteMin()
+				switch {
+				case !ok && removed != nil:
+					t.Fatalf("DeleteMin() = %v, want nil on an empty Tree", removed.Key)
+				case ok && (removed == nil || removed.Key.(Float64) != want):
+					t.Fatalf("DeleteMin() = %v, want %v", removed, want)
+				case ok:
+					drop(want)
+				}
+			case 1:
+				want, ok := maxKey(present)
+				removed := tr.DeleteMax()
+				switch {
+				case !ok && removed != nil:
+					t.Fatalf("DeleteMax() = %v, want nil on an empty Tree", removed.Key)
+				case ok && (removed == nil || removed.Key.(Float64) != want):
+					t.Fatalf("DeleteMax() = %v, want %v", removed, want)
+				case ok:
+					drop(want)
+				}
+			case 2:
+				_, wasPresent := present[key]
+				removed := tr.Delete(key)
+				switch {
+				case wasPresent && removed == nil:
+					t.Fatalf("Delete(%v) = nil, want a removed Node", key)
+				case !wasPresent && removed != nil:
+					t.Fatalf("Delete(%v) = %v, want nil: Key was never inserted", key, removed.Key)
+				case wasPresent:
+					drop(key)
+				}
+			default:
+				tr.Insert(NewNode(key))
+				present[key]++
+			}
+			checkInvariants(t, tr, "step")
+			checkAgreement(t, tr, present, "step")
+		}
+	})
+}
+
+// checkSizes asserts that nd.Size equals 1 + size(Left) + size(Right)
+// at every Node in the sub-Tree, the invariant Rank and Select rely
+// on to run in O(log n).
+func checkSizes(t *testing.T, nd *Node, step string) int {
+	t.Helper()
+	if nd == nil {
+		return 0
+	}
+	left := checkSizes(t, nd.Left, step)
+	right := checkSizes(t, nd.Right, step)
+	want := 1 + left + right
+	if nd.Size != want {
+		t.Fatalf("%s: Size = %d at key %v, want %d", step, nd.Size, nd.Key, want)
+	}
+	return want
+}
+
+// FuzzOrderStatistics inserts and deletes random Float64 keys,
+// checking Size stays correct through every rotation, then checks
+// Rank and Select round-trip against a sorted view of the Tree.
+func FuzzOrderStatistics(f *testing.F) {
+	f.Add(int64(7), uint8(48))
+	f.Add(int64(99), uint8(150))
+
+	f.Fuzz(func(t *testing.T, seed int64, n uint8) {
+		rnd := newRand(seed)
+
+		tr := New(NewNode(Float64(rnd())))
+		checkSizes(t, tr.Root, "init")
+
+		for i := 0; i < int(n); i++ {
+			key := rnd()
+			if int(key)%3 == 0 {
+				tr.Delete(Float64(key))
+			} else {
+				tr.Insert(NewNode(Float64(key)))
+			}
+			checkSizes(t, tr.Root, "step")
+		}
+
+		var sorted []Float64
+		it := tr.InOrderIterator()
+		for {
+			nd, ok := it.Next()
+			if !ok {
+				break
+			}
+			sorted = append(sorted, nd.Key.(Float64))
+		}
+
+		for i, key := range sorted {
+			if got := tr.Rank(key); got != i {
+				t.Fatalf("Rank(%v) = %d, want %d", key, got, i)
+			}
+			if got := tr.Select(i); got == nil || got.Key.(Float64) != key {
+				t.Fatalf("Select(%d) = %v, want %v", i, got, key)
+			}
+		}
+		if got := tr.Select(-1); got != nil {
+			t.Fatalf("Select(-1) = %v, want nil", got)
+		}
+		if got := tr.Select(len(sorted)); got != nil {
+			t.Fatalf("Select(%d) = %v, want nil", len(sorted), got)
+		}
+	})
+}
+
+// TestSuccessorPredecessor checks that walking Successor from the
+// minimum Node, and Predecessor from the maximum Node, both agree
+// with an InOrderIterator traversal of the same Tree, and that
+// Successor/Predecessor return nil past the maximum/minimum.
+func TestSuccessorPredecessor(t *testing.T) {
+	tr := New(NewNode(Float64(20)))
+	for _, v := range []float64{40, 10, 30, 50} {
+		tr.Insert(NewNode(Float64(v)))
+	}
+	// Keys in tr: 10, 20, 30, 40, 50.
+
+	var want []Float64
+	it := tr.InOrderIterator()
+	for {
+		nd, ok := it.Next()
+		if !ok {
+			break
+		}
+		want = append(want, nd.Key.(Float64))
+	}
+
+	var gotForward []Float64
+	nd := tr.Search(want[0])
+	for nd != nil {
+		gotForward = append(gotForward, nd.Key.(Float64))
+		nd = nd.Successor()
+	}
+	if !reflect.DeepEqual(gotForward, want) {
+		t.Fatalf("walking Successor from the minimum = %v, want %v", gotForward, want)
+	}
+
+	var gotBackward []Float64
+	nd = tr.Search(want[len(want)-1])
+	for nd != nil {
+		gotBackward = append(gotBackward, nd.Key.(Float64))
+		nd = nd.Predecessor()
+	}
+	reverse := make([]Float64, len(want))
+	for i, k := range want {
+		reverse[len(want)-1-i] = k
+	}
+	if !reflect.DeepEqual(gotBackward, reverse) {
+		t.Fatalf("walking Predecessor from the maximum = %v, want %v", gotBackward, reverse)
+	}
+}
+
+// TestFloorCeiling checks Floor and Ceiling at and around every key
+// in a small Tree, including keys below the minimum and above the
+// maximum.
+func TestFloorCeiling(t *testing.T) {
+	tr := New(NewNode(Float64(20)))
+	for _, v := range []float64{40, 10, 30, 50} {
+		tr.Insert(NewNode(Float64(v)))
+	}
+	// Keys in tr: 10, 20, 30, 40, 50.
+
+	cases := []struct {
+		key                    Float64
+		wantFloor, wantCeiling Float64
+		haveFloor, haveCeiling bool
+	}{
+		{key: 5, haveFloor: false, wantCeiling: 10, haveCeiling: true},
+		{key: 10, wantFloor: 10, haveFloor: true, wantCeiling: 10, haveCeiling: true},
+		{key: 15, wantFloor: 10, haveFloor: true, wantCeiling: 20, haveCeiling: true},
+		{key: 50, wantFloor: 50, haveFloor: true, wantCeiling: 50, haveCeiling: true},
+		{key: 60, wantFloor: 50, haveFloor: true, haveCeiling: false},
+	}
+
+	for _, c := range cases {
+		got := tr.Floor(Float64(c.key))
+		switch {
+		case !c.haveFloor && got != nil:
+			t.Fatalf("Floor(%v) = %v, want nil", c.key, got.Key)
+		case c.haveFloor && (got == nil || got.Key.(Float64) != c.wantFloor):
+			t.Fatalf("Floor(%v) = %v, want %v", c.key, got, c.wantFloor)
+		}
+
+		got = tr.Ceiling(Float64(c.key))
+		switch {
+		case !c.haveCeiling && got != nil:
+			t.Fatalf("Ceiling(%v) = %v, want nil", c.key, got.Key)
+		case c.haveCeiling && (got == nil || got.Key.(Float64) != c.wantCeiling):
+			t.Fatalf("Ceiling(%v) = %v, want %v", c.key, got, c.wantCeiling)
+		}
+	}
+}
+
+// TestRangeInOrder checks RangeInOrder visits every key in [lo, hi]
+// in ascending order and stops as soon as visit returns false.
+func TestRangeInOrder(t *testing.T) {
+	tr := New(NewNode(Float64(20)))
+	for _, v := range []float64{40, 10, 30, 50} {
+		tr.Insert(NewNode(Float64(v)))
+	}
+
+	var got []Float64
+	tr.RangeInOrder(Float64(15), Float64(45), func(nd *Node) bool {
+		got = append(got, nd.Key.(Float64))
+		return true
+	})
+	want := []Float64{20, 30, 40}
+	if len(got) != len(want) {
+		t.Fatalf("RangeInOrder(15, 45) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("RangeInOrder(15, 45)[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	var stopped []Float64
+	tr.RangeInOrder(Float64(0), Float64(100), func(nd *Node) bool {
+		stopped = append(stopped, nd.Key.(Float64))
+		return len(stopped) < 2
+	})
+	if len(stopped) != 2 {
+		t.Fatalf("RangeInOrder did not stop early: got %v", stopped)
+	}
+}
+
+// TestIteratorsAgreeWithChannels checks that PreOrderIterator,
+// InOrderIterator, and PostOrderIterator each visit the same Keys in
+// the same order as their deprecated channel-based counterpart, and
+// that Reset lets the Iterator be walked again from the start.
+func TestIteratorsAgreeWithChannels(t *testing.T) {
+	tr := New(NewNode(Float64(1)))
+	for _, v := range []float64{3, 9, 13, -5, 7} {
+		tr.Insert(NewNode(Float64(v)))
+	}
+
+	drain := func(run func(chan string)) []string {
+		ch := make(chan string)
+		go run(ch)
+		var got []string
+		for v := range ch {
+			got = append(got, v)
+		}
+		return got
+	}
+	wantPre := drain(tr.PreOrder)
+	wantIn := drain(tr.InOrder)
+	wantPost := drain(tr.PostOrder)
+
+	type iterator interface{ Next() (*Node, bool) }
+	checkIter := func(name string, want []string, it iterator) {
+		t.Helper()
+		var got []string
+		for {
+			nd, ok := it.Next()
+			if !ok {
+				break
+			}
+			got = append(got, fmt.Sprintf("%v", nd.Key))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	preIt := tr.PreOrderIterator()
+	checkIter("PreOrderIterator", wantPre, preIt)
+	preIt.Reset()
+	checkIter("PreOrderIterator after Reset", wantPre, preIt)
+
+	inIt := tr.InOrderIterator()
+	checkIter("InOrderIterator", wantIn, inIt)
+	inIt.Reset()
+	checkIter("InOrderIterator after Reset", wantIn, inIt)
+
+	postIt := tr.PostOrderIterator()
+	checkIter("PostOrderIterator", wantPost, postIt)
+	postIt.Reset()
+	checkIter("PostOrderIterator after Reset", wantPost, postIt)
+}
+
+// TestLevelOrderIterAndLevels checks LevelOrderIter agrees with the
+// channel-free LevelOrder helper on both Nodes and depths, and that
+// Levels groups those Nodes by depth in traversal order.
+func TestLevelOrderIterAndLevels(t *testing.T) {
+	tr := New(NewNode(Float64(1)))
+	for _, v := range []float64{3, 9, 13} {
+		tr.Insert(NewNode(Float64(v)))
+	}
+	// Same shape main() documents: Root 3, children 1 and 13, with 9
+	// as 13's left child ("LevelOrder: 3 1 13 9").
+
+	want := tr.LevelOrder()
+
+	it := tr.LevelOrderIter()
+	var got []*Node
+	var depths []int
+	for {
+		nd, depth, ok := it.Next()
+		if !ok {
+			break
+		}
+		got = append(got, nd)
+		depths = append(depths, depth)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("LevelOrderIter produced %d Nodes, LevelOrder produced %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("LevelOrderIter[%d] = %v, want %v", i, got[i].Key, want[i].Key)
+		}
+	}
+	wantDepths := []int{0, 1, 1, 2}
+	if !reflect.DeepEqual(depths, wantDepths) {
+		t.Fatalf("depths = %v, want %v", depths, wantDepths)
+	}
+
+	levels := tr.Levels()
+	wantCounts := []int{1, 2, 1}
+	if len(levels) != len(wantCounts) {
+		t.Fatalf("Levels() has %d levels, want %d", len(levels), len(wantCounts))
+	}
+	for depth, nodes := range levels {
+		if len(nodes) != wantCounts[depth] {
+			t.Fatalf("Levels()[%d] has %d Nodes, want %d", depth, len(nodes), wantCounts[depth])
+		}
+	}
+}
+
+// TestPersistentTreeSnapshotStable takes a Snapshot, then mutates the
+// source Tree heavily with Insert and Delete, and checks the
+// Snapshot's Len, InOrder, and black-height never change.
+func TestPersistentTreeSnapshotStable(t *testing.T) {
+	rnd := newRand(1)
+
+	tr := New(NewNode(Float64(rnd())))
+	for i := 0; i < 50; i++ {
+		tr.Insert(NewNode(Float64(rnd())))
+	}
+
+	pt := tr.Snapshot()
+	wantLen := pt.Len()
+	wantInOrder := pt.InOrder()
+	wantBlackHeight := blackHeight(pt.root)
+
+	for i := 0; i < 200; i++ {
+		key := rnd()
+		if int(key)%3 == 0 {
+			tr.Delete(Float64(key))
+		} else {
+			tr.Insert(NewNode(Float64(key)))
+		}
+	}
+	checkInvariants(t, tr, "source Tree after mutating past the Snapshot")
+
+	if got := pt.Len(); got != wantLen {
+		t.Fatalf("Snapshot Len() = %d after mutating the source Tree, want %d", got, wantLen)
+	}
+	if got := blackHeight(pt.root); got != wantBlackHeight {
+		t.Fatalf("Snapshot black-height = %d after mutating the source Tree, want %d", got, wantBlackHeight)
+	}
+	gotInOrder := pt.InOrder()
+	if len(gotInOrder) != len(wantInOrder) {
+		t.Fatalf("Snapshot InOrder() len = %d after mutating the source Tree, want %d", len(gotInOrder), len(wantInOrder))
+	}
+	for i := range wantInOrder {
+		if gotInOrder[i].Key.(Float64) != wantInOrder[i].Key.(Float64) {
+			t.Fatalf("Snapshot InOrder()[%d] = %v after mutating the source Tree, want %v", i, gotInOrder[i].Key, wantInOrder[i].Key)
+		}
+	}
+}
+
+// TestPersistentTreeInsertDelete checks PersistentTree.Insert and
+// Delete each return a new, correctly balanced PersistentTree while
+// leaving the one they were called on untouched.
+func TestPersistentTreeInsertDelete(t *testing.T) {
+	tr := New(NewNode(Float64(10)))
+	for _, v := range []float64{20, 5, 15, 25} {
+		tr.Insert(NewNode(Float64(v)))
+	}
+	base := tr.Snapshot()
+
+	inserted := base.Insert(NewNode(Float64(30)))
+	if base.Len() != 5 {
+		t.Fatalf("base.Len() = %d after Insert on a PersistentTree derived from it, want 5", base.Len())
+	}
+	if inserted.Len() != 6 {
+		t.Fatalf("inserted.Len() = %d, want 6", inserted.Len())
+	}
+	if base.Search(Float64(30)) != nil {
+		t.Fatalf("base.Search(30) found a key only inserted into the derived PersistentTree")
+	}
+	if inserted.Search(Float64(30)) == nil {
+		t.Fatalf("inserted.Search(30) = nil, want a Node")
+	}
+	checkInvariants(t, &Tree{Root: inserted.root}, "after PersistentTree.Insert")
+
+	deleted := inserted.Delete(Float64(20))
+	if deleted.Len() != 5 {
+		t.Fatalf("deleted.Len() = %d, want 5", deleted.Len())
+	}
+	if deleted.Search(Float64(20)) != nil {
+		t.Fatalf("deleted.Search(20) found a key Delete(20) should have removed")
+	}
+	if inserted.Search(Float64(20)) == nil {
+		t.Fatalf("inserted.Search(20) = nil after Delete on a PersistentTree derived from it, want a Node")
+	}
+	checkInvariants(t, &Tree{Root: deleted.root}, "after PersistentTree.Delete")
+}
+
+// newRand returns a tiny deterministic PRNG seeded from seed, good
+// enough to vary the fuzz corpus without pulling in math/rand.
+func newRand(seed int64) func() float64 {
+	state := uint64(seed)
+	if state == 0 {
+		state = 1
+	}
+	return func() float64 {
+		state ^= state << 13
+		state ^= state >> 7
+		state ^= state << 17
+		return float64(state % uint64(math.MaxInt32))
+	}
+}
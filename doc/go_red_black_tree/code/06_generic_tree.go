@@ -0,0 +1,16 @@
+package main
+
+import "joesenvv1/learn/pkg/llrb"
+
+// NewFloat64Tree returns an llrb.Tree[Float64, Float64] keyed on the
+// value itself. pkg/llrb is a parallel generic implementation of the
+// same LLRB algorithm as the Interface-based Tree in 05_traverse.go,
+// not a wrapper around it: main() still uses 05_traverse.go's Tree,
+// and pkg/llrb keeps the same order-statistic operations (Floor,
+// Ceiling, Rank, Select) so it does not fall behind as a feature set.
+// NewFloat64Tree is a thin shim for code that wants to adopt the
+// generic implementation incrementally, keyed on the same Float64
+// type, without inventing a new key type first.
+func NewFloat64Tree() *llrb.Tree[Float64, Float64] {
+	return llrb.NewTree(func(v Float64) Float64 { return v })
+}
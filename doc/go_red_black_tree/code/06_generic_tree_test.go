@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+// TestNewFloat64Tree exercises the pkg/llrb shim against the same
+// kind of Float64 keys 05_traverse_test.go fuzzes the LLRB Tree with.
+func TestNewFloat64Tree(t *testing.T) {
+	tr := NewFloat64Tree()
+
+	for _, v := range []Float64{3, 1, 13, 9} {
+		tr.Insert(v)
+	}
+	if got := tr.Len(); got != 4 {
+		t.Fatalf("Len() = %d, want 4", got)
+	}
+
+	if got, ok := tr.Get(9); !ok || got != 9 {
+		t.Fatalf("Get(9) = (%v, %v), want (9, true)", got, ok)
+	}
+	if _, ok := tr.Get(42); ok {
+		t.Fatalf("Get(42) found a key that was never inserted")
+	}
+
+	if min, ok := tr.Min(); !ok || min != 1 {
+		t.Fatalf("Min() = (%v, %v), want (1, true)", min, ok)
+	}
+	if max, ok := tr.Max(); !ok || max != 13 {
+		t.Fatalf("Max() = (%v, %v), want (13, true)", max, ok)
+	}
+
+	if !tr.Delete(1) {
+		t.Fatalf("Delete(1) = false, want true")
+	}
+	if _, ok := tr.Get(1); ok {
+		t.Fatalf("Get(1) found a key Delete(1) already removed")
+	}
+	if got := tr.Len(); got != 3 {
+		t.Fatalf("Len() after Delete = %d, want 3", got)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import "fmt"
+
+// OrderedSet is implemented by both the LLRB Tree above and AVLTree
+// below, so callers can pick the balancing strategy that fits their
+// read/write ratio (see BenchmarkInsertHeavy/BenchmarkLookupHeavy in
+// 07_avl_test.go) without changing call sites.
+type OrderedSet interface {
+	Insert(nd *Node)
+	Delete(key Interface) *Node
+	Search(key Interface) *Node
+	Min() *Node
+	Max() *Node
+	InOrder(ch chan string)
+}
+
+var (
+	_ OrderedSet = (*Tree)(nil)
+	_ OrderedSet = (*AVLTree)(nil)
+)
+
+// AVLTree is a height-balanced binary search tree (Rosetta Code AVL
+// tree task). Unlike the LLRB Tree, which only bounds black-height
+// and can be up to roughly twice as deep as an AVL tree holding the
+// same keys, AVLTree keeps every Node's balance factor in [-1, 1] by
+// rotating on the way back up from every Insert/Delete. That makes
+// Search faster at the cost of more rotations per write.
+//
+// AVLTree reuses the Node type from 05_traverse.go, but only its
+// Left, Right, Parent, Key, and Height fields; Black, Size, and
+// Frozen are meaningless here.
+type AVLTree struct {
+	Root *Node
+}
+
+// NewAVL returns a new AVLTree with its root Node.
+func NewAVL(root *Node) *AVLTree {
+	root.Height = 1
+	return &AVLTree{Root: root}
+}
+
+func height(nd *Node) int {
+	if nd == nil {
+		return 0
+	}
+	return nd.Height
+}
+
+func balanceFactor(nd *Node) int {
+	return height(nd.Left) - height(nd.Right)
+}
+
+func updateHeight(nd *Node) {
+	l, r := height(nd.Left), height(nd.Right)
+	if l > r {
+		nd.Height = l + 1
+	} else {
+		nd.Height = r + 1
+	}
+}
+
+func avlRotateLeft(nd *Node) *Node {
+	x := nd.Right
+	nd.Right = x.Left
+	if nd.Right != nil {
+		nd.Right.Parent = nd
+	}
+	x.Left = nd
+
+	x.Parent = nd.Parent
+	nd.Parent = x
+
+	updateHeight(nd)
+	updateHeight(x)
+	return x
+}
+
+func avlRotateRight(nd *Node) *Node {
+	x := nd.Left
+	nd.Left = x.Right
+	if nd.Left != nil {
+		nd.Left.Parent = nd
+	}
+	x.Right = nd
+
+	x.Parent = nd.Parent
+	nd.Parent = x
+
+	updateHeight(nd)
+	updateHeight(x)
+	return x
+}
+
+// avlBalance refreshes nd's Height and, if its balance factor has
+// drifted outside [-1, 1], rotates nd back into shape using the
+// standard LL/LR/RL/RR cases.
+func avlBalance(nd *Node) *Node {
+	updateHeight(nd)
+	switch bf := balanceFactor(nd); {
+	case bf > 1:
+		if balanceFactor(nd.Left) < 0 {
+			nd.Left = avlRotateLeft(nd.Left) // LR
+		}
+		return avlRotateRight(nd) // LL
+	case bf < -1:
+		if balanceFactor(nd.Right) > 0 {
+			nd.Right = avlRotateRight(nd.Right) // RL
+		}
+		return avlRotateLeft(nd) // RR
+	default:
+		return nd
+	}
+}
+
+// avlInsert inserts nd2 with nd1 as a root, mirroring (*Node).insert
+// above: equal keys fall to the left rather than replacing.
+func avlInsert(nd1, nd2 *Node) *Node {
+	if nd1 == nil {
+		nd2.Height = 1
+		return nd2
+	}
+	if nd1.Key.Less(nd2.Key) {
+		nd1.Right = avlInsert(nd1.Right, nd2)
+		nd1.Right.Parent = nd1
+	} else {
+		nd1.Left = avlInsert(nd1.Left, nd2)
+		nd1.Left.Parent = nd1
+	}
+	return avlBalance(nd1)
+}
+
+// Insert adds a Node to the AVLTree and rebalances on the way back up
+// so every Node's balance factor stays within [-1, 1].
+func (tr *AVLTree) Insert(nd *Node) {
+	if tr.Root == nd {
+		return
+	}
+	if tr.Root == nil {
+		nd.Height = 1
+		tr.Root = nd
+		return
+	}
+	tr.Root = avlInsert(tr.Root, nd)
+	tr.Root.Parent = nil
+}
+
+// avlDeleteMin removes and returns the smallest Node in the sub-Tree
+// rooted at nd, returning the rebalanced sub-Tree alongside it.
+func avlDeleteMin(nd *Node) (*Node, *Node) {
+	if nd.Left == nil {
+		return nd.Right, nd
+	}
+	var removed *Node
+	nd.Left, removed = avlDeleteMin(nd.Left)
+	if nd.Left != nil {
+		nd.Left.Parent = nd
+	}
+	return avlBalance(nd), removed
+}
+
+// avlDeleteKey removes key from the sub-Tree rooted at nd, swapping
+// in the in-order successor when deleting an internal Node, and
+// rebalances on the way back up.
+func avlDeleteKey(nd *Node, key Interface) (*Node, *Node) {
+	if nd == nil {
+		return nil, nil
+	}
+	var removed *Node
+	switch {
+	case key.Less(nd.Key):
+		nd.Left, removed = avlDeleteKey(nd.Left, key)
+		if nd.Left != nil {
+			nd.Left.Parent = nd
+		}
+	case nd.Key.Less(key):
+		nd.Right, removed = avlDeleteKey(nd.Right, key)
+		if nd.Right != nil {
+			nd.Right.Parent = nd
+		}
+	default:
+		removed = nd
+		switch {
+		case nd.Left == nil:
+			return nd.Right, removed
+		case nd.Right == nil:
+			return nd.Left, removed
+		default:
+			var succ *Node
+			nd.Right, succ = avlDeleteMin(nd.Right)
+			succ.Left, succ.Right = nd.Left, nd.Right
+			if succ.Left != nil {
+				succ.Left.Parent = succ
+			}
+			if succ.Right != nil {
+				succ.Right.Parent = succ
+			}
+			nd = succ
+		}
+	}
+	return avlBalance(nd), removed
+}
+
+// Delete removes the Node with the given key from the AVLTree and
+// returns a detached copy of what was removed, or nil if key is not
+// found.
+func (tr *AVLTree) Delete(key Interface) *Node {
+	if tr.Root == nil {
+		return nil
+	}
+	var removed *Node
+	tr.Root, removed = avlDeleteKey(tr.Root, key)
+	if tr.Root != nil {
+		tr.Root.Parent = nil
+	}
+	if removed == nil {
+		return nil
+	}
+	return &Node{Key: removed.Key}
+}
+
+// Search does binary-search on a given key and returns the first Node with the key.
+func (tr *AVLTree) Search(key Interface) *Node {
+	nd := tr.Root
+	for nd != nil {
+		switch {
+		case nd.Key.Less(key):
+			nd = nd.Right
+		case key.Less(nd.Key):
+			nd = nd.Left
+		default:
+			return nd
+		}
+	}
+	return nil
+}
+
+// Min returns the minimum key Node in the tree.
+func (tr *AVLTree) Min() *Node {
+	nd := tr.Root
+	if nd == nil {
+		return nil
+	}
+	for nd.Left != nil {
+		nd = nd.Left
+	}
+	return nd
+}
+
+// Max returns the maximum key Node in the tree.
+func (tr *AVLTree) Max() *Node {
+	nd := tr.Root
+	if nd == nil {
+		return nil
+	}
+	for nd.Right != nil {
+		nd = nd.Right
+	}
+	return nd
+}
+
+// InOrder traverses from Left-SubTree, Root, and Right-SubTree. (DFS)
+func (tr *AVLTree) InOrder(ch chan string) {
+	avlInOrder(tr.Root, ch)
+	close(ch)
+}
+
+func avlInOrder(nd *Node, ch chan string) {
+	if nd == nil {
+		return
+	}
+	avlInOrder(nd.Left, ch)
+	ch <- fmt.Sprintf("%v", nd.Key)
+	avlInOrder(nd.Right, ch)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// checkAVLInvariants asserts that every Node in tr has a balance
+// factor within [-1, 1] and a Height consistent with its children.
+func checkAVLInvariants(t *testing.T, tr *AVLTree, step string) {
+	t.Helper()
+	var walk func(nd *Node) int
+	walk = func(nd *Node) int {
+		if nd == nil {
+			return 0
+		}
+		l := walk(nd.Left)
+		r := walk(nd.Right)
+		if bf := l - r; bf > 1 || bf < -1 {
+			t.Fatalf("%s: balance factor %d out of range at key %v", step, bf, nd.Key)
+		}
+		want := 1 + max(l, r)
+		if nd.Height != want {
+			t.Fatalf("%s: Height %d at key %v, want %d", step, nd.Height, nd.Key, want)
+		}
+		return want
+	}
+	walk(tr.Root)
+}
+
+// checkAVLAgreement asserts that tr holds exactly the multiset of
+// Keys recorded in present, both in Search membership and in the
+// full in-order Key sequence. Comparing the whole sequence, not just
+// the Key an operation just touched, is what would catch
+// avlDeleteKey's successor swap picking the wrong Node to remove,
+// the same class of bug deleteKey had (fixed in 5199a16) before
+// 932bb10 gave the LLRB fuzz tests this same oracle.
+func checkAVLAgreement(t *testing.T, tr *AVLTree, present map[Float64]int, step string) {
+	t.Helper()
+
+	var want []Float64
+	for k, count := range present {
+		for i := 0; i < count; i++ {
+			want = append(want, k)
+		}
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+
+	var got []Float64
+	var walk func(nd *Node)
+	walk = func(nd *Node) {
+		if nd == nil {
+			return
+		}
+		walk(nd.Left)
+		got = append(got, nd.Key.(Float64))
+		walk(nd.Right)
+	}
+	walk(tr.Root)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: AVLTree Keys = %v, want %v", step, got, want)
+	}
+	for k := range present {
+		if tr.Search(k) == nil {
+			t.Fatalf("%s: Search(%v) = nil, want a Node", step, k)
+		}
+	}
+}
+
+// FuzzAVLInsertDelete inserts and deletes random Float64 keys and
+// checks the AVL balance invariant holds after every single Insert
+// and Delete, and that the Tree's contents agree with a
+// map[Float64]int oracle mirroring the same operations.
+func FuzzAVLInsertDelete(f *testing.F) {
+	f.Add(int64(1), uint8(64))
+	f.Add(int64(42), uint8(200))
+
+	f.Fuzz(func(t *testing.T, seed int64, n uint8) {
+		rnd := newRand(seed)
+
+		init := Float64(rnd())
+		tr := NewAVL(NewNode(init))
+		present := map[Float64]int{init: 1}
+		checkAVLInvariants(t, tr, "init")
+		checkAVLAgreement(t, tr, present, "init")
+
+		for i := 0; i < int(n); i++ {
+			key := Float64(rnd())
+			if int(key)%3 == 0 {
+				_, wasPresent := present[key]
+				removed := tr.Delete(key)
+				switch {
+				case wasPresent && removed == nil:
+					t.Fatalf("Delete(%v) = nil, want a removed Node", key)
+				case !wasPresent && removed != nil:
+					t.Fatalf("Delete(%v) = %v, want nil: Key was never inserted", key, removed.Key)
+				case wasPresent:
+					present[key]--
+					if present[key] == 0 {
+						delete(present, key)
+					}
+				}
+			} else {
+				tr.Insert(NewNode(key))
+				present[key]++
+			}
+			checkAVLInvariants(t, tr, "step")
+			checkAVLAgreement(t, tr, present, "step")
+		}
+	})
+}
+
+// benchKeys returns n deterministic pseudo-random Float64 keys.
+func benchKeys(n int) []Float64 {
+	rnd := newRand(1)
+	keys := make([]Float64, n)
+	for i := range keys {
+		keys[i] = Float64(rnd())
+	}
+	return keys
+}
+
+// BenchmarkInsertHeavy compares Tree (LLRB) against AVLTree building
+// up an n-key tree from scratch, one Insert at a time.
+func BenchmarkInsertHeavy(b *testing.B) {
+	keys := benchKeys(10000)
+
+	b.Run("LLRB", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tr := New(NewNode(keys[0]))
+			for _, k := range keys[1:] {
+				tr.Insert(NewNode(k))
+			}
+		}
+	})
+	b.Run("AVL", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tr := NewAVL(NewNode(keys[0]))
+			for _, k := range keys[1:] {
+				tr.Insert(NewNode(k))
+			}
+		}
+	})
+}
+
+// BenchmarkLookupHeavy builds one tree of each kind up front, then
+// times repeated Search calls, the workload AVL's tighter balance is
+// meant to pay off on.
+func BenchmarkLookupHeavy(b *testing.B) {
+	keys := benchKeys(10000)
+
+	llrb := New(NewNode(keys[0]))
+	avl := NewAVL(NewNode(keys[0]))
+	for _, k := range keys[1:] {
+		llrb.Insert(NewNode(k))
+		avl.Insert(NewNode(k))
+	}
+
+	b.Run("LLRB", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			llrb.Search(keys[i%len(keys)])
+		}
+	})
+	b.Run("AVL", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			avl.Search(keys[i%len(keys)])
+		}
+	})
+}
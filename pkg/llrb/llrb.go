@@ -0,0 +1,436 @@
+// Package llrb implements a generic left-leaning red-black tree
+// (Sedgewick, https://sedgewick.io/wp-content/themes/sedgewick/papers/2008LLRB.pdf).
+//
+// Unlike the Interface-based Tree in doc/go_red_black_tree, Tree[K, V]
+// stores values of any type and derives the ordering key with a KeyFn,
+// so callers never wrap their values in an interface{} or pay for a
+// type assertion inside Less. It carries the same order-statistic
+// operations (Floor, Ceiling, Rank, Select) as the Interface-based
+// Tree, so the two stay in step as features are added.
+package llrb
+
+import (
+	"iter"
+
+	"golang.org/x/exp/constraints"
+)
+
+// KeyFn extracts the ordering key K from a value V.
+type KeyFn[K constraints.Ordered, V any] func(V) K
+
+// node is a single element of a Tree.
+type node[K constraints.Ordered, V any] struct {
+	left, right, parent *node[K, V]
+	key                 K
+	value               V
+	black               bool // true when the color of the parent link is black.
+	size                int  // 1 + size(left) + size(right); see size().
+}
+
+func isRed[K constraints.Ordered, V any](nd *node[K, V]) bool {
+	return nd != nil && !nd.black
+}
+
+// size returns the subtree count rooted at nd, or 0 for a nil node.
+func size[K constraints.Ordered, V any](nd *node[K, V]) int {
+	if nd == nil {
+		return 0
+	}
+	return nd.size
+}
+
+// Tree is a left-leaning red-black tree ordered by KeyFn(value).
+type Tree[K constraints.Ordered, V any] struct {
+	root  *node[K, V]
+	keyFn KeyFn[K, V]
+	size  int
+}
+
+// NewTree returns an empty Tree ordered by keyFn.
+func NewTree[K constraints.Ordered, V any](keyFn KeyFn[K, V]) *Tree[K, V] {
+	return &Tree[K, V]{keyFn: keyFn}
+}
+
+// Len returns the number of values in the Tree.
+func (tr *Tree[K, V]) Len() int { return tr.size }
+
+func rotateLeft[K constraints.Ordered, V any](nd *node[K, V]) *node[K, V] {
+	x := nd.right
+	nd.right = x.left
+	if nd.right != nil {
+		nd.right.parent = nd
+	}
+	x.left = nd
+	x.parent = nd.parent
+	nd.parent = x
+	x.black = nd.black
+	nd.black = false
+	nd.size = 1 + size(nd.left) + size(nd.right)
+	x.size = 1 + size(x.left) + size(x.right)
+	return x
+}
+
+func rotateRight[K constraints.Ordered, V any](nd *node[K, V]) *node[K, V] {
+	x := nd.left
+	nd.left = x.right
+	if nd.left != nil {
+		nd.left.parent = nd
+	}
+	x.right = nd
+	x.parent = nd.parent
+	nd.parent = x
+	x.black = nd.black
+	nd.black = false
+	nd.size = 1 + size(nd.left) + size(nd.right)
+	x.size = 1 + size(x.left) + size(x.right)
+	return x
+}
+
+func flipColor[K constraints.Ordered, V any](nd *node[K, V]) {
+	nd.black = !nd.black
+	nd.left.black = !nd.left.black
+	nd.right.black = !nd.right.black
+}
+
+func moveRedLeft[K constraints.Ordered, V any](nd *node[K, V]) *node[K, V] {
+	flipColor(nd)
+	if isRed(nd.right.left) {
+		nd.right = rotateRight(nd.right)
+		nd = rotateLeft(nd)
+		flipColor(nd)
+	}
+	return nd
+}
+
+func moveRedRight[K constraints.Ordered, V any](nd *node[K, V]) *node[K, V] {
+	flipColor(nd)
+	if isRed(nd.left.left) {
+		nd = rotateRight(nd)
+		flipColor(nd)
+	}
+	return nd
+}
+
+// balance restores the LLRB invariants on the way up from an Insert.
+func balance[K constraints.Ordered, V any](nd *node[K, V]) *node[K, V] {
+	if isRed(nd.right) && !isRed(nd.left) {
+		nd = rotateLeft(nd)
+	}
+	if isRed(nd.left) && isRed(nd.left.left) {
+		nd = rotateRight(nd)
+	}
+	if isRed(nd.left) && isRed(nd.right) {
+		flipColor(nd)
+	}
+	return nd
+}
+
+// fixUp restores the LLRB invariants on the way up from a Delete.
+func fixUp[K constraints.Ordered, V any](nd *node[K, V]) *node[K, V] {
+	if isRed(nd.right) {
+		nd = rotateLeft(nd)
+	}
+	if isRed(nd.left) && isRed(nd.left.left) {
+		nd = rotateRight(nd)
+	}
+	if isRed(nd.left) && isRed(nd.right) {
+		flipColor(nd)
+	}
+	return nd
+}
+
+// Insert adds value to the Tree, replacing the Value of any existing
+// node with the same key.
+func (tr *Tree[K, V]) Insert(value V) {
+	key := tr.keyFn(value)
+	inserted := false
+	tr.root = insert(tr.root, key, value, &inserted)
+	tr.root.black = true
+	tr.root.parent = nil
+	if inserted {
+		tr.size++
+	}
+}
+
+func insert[K constraints.Ordered, V any](nd *node[K, V], key K, value V, inserted *bool) *node[K, V] {
+	if nd == nil {
+		*inserted = true
+		return &node[K, V]{key: key, value: value, size: 1}
+	}
+	switch {
+	case key < nd.key:
+		nd.left = insert(nd.left, key, value, inserted)
+		nd.left.parent = nd
+	case nd.key < key:
+		nd.right = insert(nd.right, key, value, inserted)
+		nd.right.parent = nd
+	default:
+		nd.value = value
+	}
+	nd.size = 1 + size(nd.left) + size(nd.right)
+	return balance(nd)
+}
+
+// Get returns the Value stored under key and reports whether it was
+// found.
+func (tr *Tree[K, V]) Get(key K) (V, bool) {
+	nd := tr.root
+	for nd != nil {
+		switch {
+		case key < nd.key:
+			nd = nd.left
+		case nd.key < key:
+			nd = nd.right
+		default:
+			return nd.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+func deleteMin[K constraints.Ordered, V any](nd *node[K, V]) *node[K, V] {
+	if nd.left == nil {
+		return nil
+	}
+	if !isRed(nd.left) && !isRed(nd.left.left) {
+		nd = moveRedLeft(nd)
+	}
+	nd.left = deleteMin(nd.left)
+	if nd.left != nil {
+		nd.left.parent = nd
+	}
+	nd.size = 1 + size(nd.left) + size(nd.right)
+	return fixUp(nd)
+}
+
+func deleteKey[K constraints.Ordered, V any](nd *node[K, V], key K) *node[K, V] {
+	if key < nd.key {
+		if !isRed(nd.left) && !isRed(nd.left.left) {
+			nd = moveRedLeft(nd)
+		}
+		nd.left = deleteKey(nd.left, key)
+		if nd.left != nil {
+			nd.left.parent = nd
+		}
+	} else {
+		if isRed(nd.left) {
+			nd = rotateRight(nd)
+		}
+		if nd.key == key && nd.right == nil {
+			return nil
+		}
+		if !isRed(nd.right) && !isRed(nd.right.left) {
+			nd = moveRedRight(nd)
+		}
+		if nd.key == key {
+			m := nd.right
+			for m.left != nil {
+				m = m.left
+			}
+			nd.key, nd.value = m.key, m.value
+			nd.right = deleteMin(nd.right)
+		} else {
+			nd.right = deleteKey(nd.right, key)
+		}
+		if nd.right != nil {
+			nd.right.parent = nd
+		}
+	}
+	nd.size = 1 + size(nd.left) + size(nd.right)
+	return fixUp(nd)
+}
+
+// Delete removes key from the Tree and reports whether it was found.
+func (tr *Tree[K, V]) Delete(key K) bool {
+	if tr.root == nil {
+		return false
+	}
+	if _, ok := tr.Get(key); !ok {
+		return false
+	}
+	if !isRed(tr.root.left) && !isRed(tr.root.right) {
+		tr.root.black = false
+	}
+	tr.root = deleteKey(tr.root, key)
+	if tr.root != nil {
+		tr.root.black = true
+		tr.root.parent = nil
+	}
+	tr.size--
+	return true
+}
+
+// Min returns the smallest key's Value and reports whether the Tree
+// is non-empty.
+func (tr *Tree[K, V]) Min() (V, bool) {
+	nd := tr.root
+	if nd == nil {
+		var zero V
+		return zero, false
+	}
+	for nd.left != nil {
+		nd = nd.left
+	}
+	return nd.value, true
+}
+
+// Max returns the largest key's Value and reports whether the Tree
+// is non-empty.
+func (tr *Tree[K, V]) Max() (V, bool) {
+	nd := tr.root
+	if nd == nil {
+		var zero V
+		return zero, false
+	}
+	for nd.right != nil {
+		nd = nd.right
+	}
+	return nd.value, true
+}
+
+// Floor returns the Value stored under the largest key <= key, and
+// reports whether one exists.
+func (tr *Tree[K, V]) Floor(key K) (V, bool) {
+	nd := floor(tr.root, key)
+	if nd == nil {
+		var zero V
+		return zero, false
+	}
+	return nd.value, true
+}
+
+func floor[K constraints.Ordered, V any](nd *node[K, V], key K) *node[K, V] {
+	if nd == nil {
+		return nil
+	}
+	switch {
+	case nd.key == key:
+		return nd
+	case key < nd.key:
+		return floor(nd.left, key)
+	default:
+		if f := floor(nd.right, key); f != nil {
+			return f
+		}
+		return nd
+	}
+}
+
+// Ceiling returns the Value stored under the smallest key >= key, and
+// reports whether one exists.
+func (tr *Tree[K, V]) Ceiling(key K) (V, bool) {
+	nd := ceiling(tr.root, key)
+	if nd == nil {
+		var zero V
+		return zero, false
+	}
+	return nd.value, true
+}
+
+func ceiling[K constraints.Ordered, V any](nd *node[K, V], key K) *node[K, V] {
+	if nd == nil {
+		return nil
+	}
+	switch {
+	case nd.key == key:
+		return nd
+	case nd.key < key:
+		return ceiling(nd.right, key)
+	default:
+		if c := ceiling(nd.left, key); c != nil {
+			return c
+		}
+		return nd
+	}
+}
+
+// Rank returns the number of keys in the Tree strictly less than key.
+func (tr *Tree[K, V]) Rank(key K) int {
+	return rank(tr.root, key)
+}
+
+func rank[K constraints.Ordered, V any](nd *node[K, V], key K) int {
+	if nd == nil {
+		return 0
+	}
+	switch {
+	case key < nd.key:
+		return rank(nd.left, key)
+	case nd.key < key:
+		return 1 + size(nd.left) + rank(nd.right, key)
+	default:
+		return size(nd.left)
+	}
+}
+
+// Select returns the Value holding the k-th smallest key (0-indexed)
+// and reports whether k was in range.
+func (tr *Tree[K, V]) Select(k int) (V, bool) {
+	if k < 0 || k >= size(tr.root) {
+		var zero V
+		return zero, false
+	}
+	nd := selectNode(tr.root, k)
+	return nd.value, true
+}
+
+func selectNode[K constraints.Ordered, V any](nd *node[K, V], k int) *node[K, V] {
+	t := size(nd.left)
+	switch {
+	case k < t:
+		return selectNode(nd.left, k)
+	case k > t:
+		return selectNode(nd.right, k-t-1)
+	default:
+		return nd
+	}
+}
+
+// Range calls fn for every Value with a key in [lo, hi], in ascending
+// key order, stopping early if fn returns false.
+func (tr *Tree[K, V]) Range(lo, hi K, fn func(V) bool) {
+	rangeVisit(tr.root, lo, hi, fn)
+}
+
+func rangeVisit[K constraints.Ordered, V any](nd *node[K, V], lo, hi K, fn func(V) bool) bool {
+	if nd == nil {
+		return true
+	}
+	if lo < nd.key {
+		if !rangeVisit(nd.left, lo, hi, fn) {
+			return false
+		}
+	}
+	if !(nd.key < lo) && !(hi < nd.key) {
+		if !fn(nd.value) {
+			return false
+		}
+	}
+	if nd.key < hi {
+		return rangeVisit(nd.right, lo, hi, fn)
+	}
+	return true
+}
+
+// All returns an iterator over every (key, value) pair in ascending
+// key order, using an explicit stack so a caller that breaks out of
+// the range-over-func loop early never leaks a goroutine.
+func (tr *Tree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		var stack []*node[K, V]
+		nd := tr.root
+		for nd != nil || len(stack) > 0 {
+			for nd != nil {
+				stack = append(stack, nd)
+				nd = nd.left
+			}
+			nd = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(nd.key, nd.value) {
+				return
+			}
+			nd = nd.right
+		}
+	}
+}
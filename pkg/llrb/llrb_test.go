@@ -0,0 +1,287 @@
+package llrb
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/constraints"
+)
+
+// blackHeight walks every root-to-nil path under nd and returns the
+// common black-height, or -1 if any two paths disagree or a red node
+// has a red child.
+func blackHeight[K constraints.Ordered, V any](nd *node[K, V]) int {
+	if nd == nil {
+		return 0
+	}
+	if isRed(nd) && (isRed(nd.left) || isRed(nd.right)) {
+		return -1
+	}
+	left := blackHeight(nd.left)
+	if left == -1 {
+		return -1
+	}
+	right := blackHeight(nd.right)
+	if right == -1 {
+		return -1
+	}
+	if left != right {
+		return -1
+	}
+	if nd.black {
+		return left + 1
+	}
+	return left
+}
+
+// checkInvariants asserts the LLRB invariants on tr: the root is
+// black, no red node has a red child, and every root-to-nil path has
+// the same black-height.
+func checkInvariants(t *testing.T, tr *Tree[float64, float64], step string) {
+	t.Helper()
+	if tr.root == nil {
+		return
+	}
+	if !tr.root.black {
+		t.Fatalf("%s: root is red", step)
+	}
+	if blackHeight(tr.root) == -1 {
+		t.Fatalf("%s: red-black invariant violated", step)
+	}
+}
+
+// checkAgreement asserts that tr holds exactly the key set recorded
+// in present, both via Get and via the full ascending All() sequence.
+// Comparing the whole sequence, not just the key an operation just
+// touched, is what would have caught deleteKey picking the wrong node
+// to remove (the bug fixed in 05_traverse.go by 5199a16): that bug
+// could leave the requested key in the Tree while silently dropping a
+// different one.
+func checkAgreement(t *testing.T, tr *Tree[float64, float64], present map[float64]bool, step string) {
+	t.Helper()
+
+	if got := tr.Len(); got != len(present) {
+		t.Fatalf("%s: Len() = %d, want %d", step, got, len(present))
+	}
+
+	var want []float64
+	for k := range present {
+		want = append(want, k)
+	}
+	sort.Float64s(want)
+
+	var got []float64
+	for k := range tr.All() {
+		got = append(got, k)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: All() Keys = %v, want %v", step, got, want)
+	}
+	for k := range present {
+		if _, ok := tr.Get(k); !ok {
+			t.Fatalf("%s: Get(%v) = not found, want found", step, k)
+		}
+	}
+}
+
+// FuzzInsertDelete inserts and deletes random float64 keys and checks
+// the red-black invariants hold after every single Insert and Delete,
+// and that the Tree's contents agree with a map[float64]bool oracle
+// mirroring the same operations, mirroring 05_traverse_test.go's
+// FuzzInsertDelete for the Interface-based Tree.
+func FuzzInsertDelete(f *testing.F) {
+	f.Add(int64(1), uint8(64))
+	f.Add(int64(42), uint8(200))
+
+	f.Fuzz(func(t *testing.T, seed int64, n uint8) {
+		rnd := newRand(seed)
+
+		tr := NewTree(func(v float64) float64 { return v })
+		first := rnd()
+		tr.Insert(first)
+		present := map[float64]bool{first: true}
+		checkInvariants(t, tr, "init")
+		checkAgreement(t, tr, present, "init")
+
+		for i := 0; i < int(n); i++ {
+			key := rnd()
+			if int(key)%3 == 0 {
+				wasPresent := present[key]
+				ok := tr.Delete(key)
+				if ok != wasPresent {
+					t.Fatalf("Delete(%v) = %v, want %v", key, ok, wasPresent)
+				}
+				delete(present, key)
+			} else {
+				tr.Insert(key)
+				present[key] = true
+			}
+			checkInvariants(t, tr, "step")
+			checkAgreement(t, tr, present, "step")
+		}
+
+		count := 0
+		for range tr.All() {
+			count++
+		}
+		if count != tr.Len() {
+			t.Fatalf("All() produced %d entries, Len() = %d", count, tr.Len())
+		}
+	})
+}
+
+// checkSizes asserts that nd.size equals 1 + size(Left) + size(Right)
+// at every node in the sub-Tree, the invariant Rank and Select rely
+// on to run in O(log n).
+func checkSizes[K constraints.Ordered, V any](t *testing.T, nd *node[K, V], step string) int {
+	t.Helper()
+	if nd == nil {
+		return 0
+	}
+	left := checkSizes(t, nd.left, step)
+	right := checkSizes(t, nd.right, step)
+	want := 1 + left + right
+	if nd.size != want {
+		t.Fatalf("%s: size = %d at key %v, want %d", step, nd.size, nd.key, want)
+	}
+	return want
+}
+
+// FuzzOrderStatistics inserts and deletes random float64 keys,
+// checking size stays correct through every rotation, then checks
+// Rank and Select round-trip against a sorted view of the Tree,
+// mirroring 05_traverse_test.go's FuzzOrderStatistics.
+func FuzzOrderStatistics(f *testing.F) {
+	f.Add(int64(7), uint8(48))
+	f.Add(int64(99), uint8(150))
+
+	f.Fuzz(func(t *testing.T, seed int64, n uint8) {
+		rnd := newRand(seed)
+
+		tr := NewTree(func(v float64) float64 { return v })
+		tr.Insert(rnd())
+		checkSizes(t, tr.root, "init")
+
+		for i := 0; i < int(n); i++ {
+			key := rnd()
+			if int(key)%3 == 0 {
+				tr.Delete(key)
+			} else {
+				tr.Insert(key)
+			}
+			checkSizes(t, tr.root, "step")
+		}
+
+		var sorted []float64
+		for k := range tr.All() {
+			sorted = append(sorted, k)
+		}
+
+		for i, key := range sorted {
+			if got := tr.Rank(key); got != i {
+				t.Fatalf("Rank(%v) = %d, want %d", key, got, i)
+			}
+			if got, ok := tr.Select(i); !ok || got != key {
+				t.Fatalf("Select(%d) = (%v, %v), want (%v, true)", i, got, ok, key)
+			}
+		}
+		if _, ok := tr.Select(-1); ok {
+			t.Fatalf("Select(-1) found a key, want none")
+		}
+		if _, ok := tr.Select(len(sorted)); ok {
+			t.Fatalf("Select(%d) found a key, want none", len(sorted))
+		}
+	})
+}
+
+// TestFloorCeiling checks Floor and Ceiling at and around every key
+// in a small Tree, including keys below the minimum and above the
+// maximum.
+func TestFloorCeiling(t *testing.T) {
+	tr := NewTree(func(v float64) float64 { return v })
+	for _, v := range []float64{20, 40, 10, 30, 50} {
+		tr.Insert(v)
+	}
+	// Keys in tr: 10, 20, 30, 40, 50.
+
+	cases := []struct {
+		key                    float64
+		wantFloor, wantCeiling float64
+		haveFloor, haveCeiling bool
+	}{
+		{key: 5, haveFloor: false, wantCeiling: 10, haveCeiling: true},
+		{key: 10, wantFloor: 10, haveFloor: true, wantCeiling: 10, haveCeiling: true},
+		{key: 15, wantFloor: 10, haveFloor: true, wantCeiling: 20, haveCeiling: true},
+		{key: 50, wantFloor: 50, haveFloor: true, wantCeiling: 50, haveCeiling: true},
+		{key: 60, wantFloor: 50, haveFloor: true, haveCeiling: false},
+	}
+
+	for _, c := range cases {
+		got, ok := tr.Floor(c.key)
+		if ok != c.haveFloor || (ok && got != c.wantFloor) {
+			t.Fatalf("Floor(%v) = (%v, %v), want (%v, %v)", c.key, got, ok, c.wantFloor, c.haveFloor)
+		}
+
+		got, ok = tr.Ceiling(c.key)
+		if ok != c.haveCeiling || (ok && got != c.wantCeiling) {
+			t.Fatalf("Ceiling(%v) = (%v, %v), want (%v, %v)", c.key, got, ok, c.wantCeiling, c.haveCeiling)
+		}
+	}
+}
+
+// TestRangeAndAll checks Range and All agree with each other and
+// visit keys in ascending order.
+func TestRangeAndAll(t *testing.T) {
+	tr := NewTree(func(v float64) float64 { return v })
+	for _, v := range []float64{5, 1, 9, 3, 7} {
+		tr.Insert(v)
+	}
+
+	var all []float64
+	for k := range tr.All() {
+		all = append(all, k)
+	}
+	want := []float64{1, 3, 5, 7, 9}
+	if len(all) != len(want) {
+		t.Fatalf("All() = %v, want %v", all, want)
+	}
+	for i, k := range want {
+		if all[i] != k {
+			t.Fatalf("All()[%d] = %v, want %v", i, all[i], k)
+		}
+	}
+
+	var ranged []float64
+	tr.Range(3, 7, func(v float64) bool {
+		ranged = append(ranged, v)
+		return true
+	})
+	wantRange := []float64{3, 5, 7}
+	if len(ranged) != len(wantRange) {
+		t.Fatalf("Range(3, 7) = %v, want %v", ranged, wantRange)
+	}
+	for i, k := range wantRange {
+		if ranged[i] != k {
+			t.Fatalf("Range(3, 7)[%d] = %v, want %v", i, ranged[i], k)
+		}
+	}
+}
+
+// newRand returns a tiny deterministic PRNG seeded from seed, good
+// enough to vary the fuzz corpus without pulling in math/rand (see
+// 05_traverse_test.go's newRand).
+func newRand(seed int64) func() float64 {
+	state := uint64(seed)
+	if state == 0 {
+		state = 1
+	}
+	return func() float64 {
+		state ^= state << 13
+		state ^= state >> 7
+		state ^= state << 17
+		return float64(state % uint64(math.MaxInt32))
+	}
+}